@@ -2,15 +2,9 @@ package main
 
 import (
 	"fmt"
-)
-
-type FooEntity interface {
-	getID() int
-}
 
-type BarEntity interface {
-	getName() string
-}
+	"github.com/genxium/GoStructPrac/pkg/entity"
+)
 
 type TestPlayer struct {
 	pID   *int
@@ -18,14 +12,12 @@ type TestPlayer struct {
 }
 
 func (player TestPlayer) getID() int {
-	// Implementing `FooEntity`.
 	var ID int
 	ID = *(player.pID)
 	return ID
 }
 
 func (pPlayer *TestPlayer) getName() string {
-	// Implementing `BarEntity`.
 	/**
 	 * NOTE: This kind of method is callable by a `player TestPlayer` instance as well.
 	 */
@@ -36,23 +28,45 @@ func (pPlayer *TestPlayer) getName() string {
 	return name
 }
 
-func printIDOfFooEntityByCastingToStruct(e FooEntity) {
-	// Reference https://tour.golang.org/methods/15.
-	testPlayerIns := e.(TestPlayer)
-	var ID int
-	ID = *(testPlayerIns.pID)
-	fmt.Printf("%d\n", ID)
+// EntityID implements `entity.Entity[int]`, the replacement for the old
+// `e.(TestPlayer)` / `e.(*TestPlayer)` type-assertion dance.
+func (pPlayer *TestPlayer) EntityID() int {
+	return *(pPlayer.pID)
 }
 
-func printNameOfBarEntityByCastingToPtrToStruct(e BarEntity) {
-	pTestPlayerIns := e.(*TestPlayer)
-	testPlayerIns := *pTestPlayerIns
-	var name string
-	name = *(testPlayerIns.pName)
-	fmt.Printf("%s\n", name)
+// namedEntity is entity.Entity[int] plus a name accessor -- the replacement
+// for the old standalone `BarEntity` interface. It's kept separate from
+// entity.Entity[int] itself because `*Player` (see main.go) implements the
+// latter but has no name-access method of its own.
+type namedEntity interface {
+	entity.Entity[int]
+	getName() string
+}
+
+func printIDOfEntity(e entity.Entity[int]) {
+	// Reference https://tour.golang.org/methods/15. No cast needed: any
+	// `entity.Entity[int]`, regardless of its concrete type, exposes EntityID().
+	fmt.Printf("%d\n", e.EntityID())
+}
+
+func printNameOfEntity(e namedEntity) {
+	// Same idea as printIDOfEntity above, replacing the old
+	// `printNameOfBarEntityByCastingToPtrToStruct`'s `e.(*TestPlayer)` cast.
+	fmt.Printf("%s\n", e.getName())
+}
+
+func printIDsOfAllEntitiesInRegistry(reg *entity.Registry[entity.Entity[int], int]) {
+	// `*TestPlayer` and `*Player` (see main.go) both implement `entity.Entity[int]`
+	// and can be stored in, and read back out of, the very same registry.
+	ids := entity.View(reg, func(e entity.Entity[int]) int { return e.EntityID() })
+	fmt.Printf("Registry now holds entity IDs: %v\n", ids)
 }
 
-func main() {
+// runEntityDemo exercises pkg/entity the same way the old standalone
+// `basics.go` program (with its own `main`) used to; it's now called from
+// main.go's single `main` instead (see there) so the package has only one
+// entrypoint.
+func runEntityDemo() {
 	ID := 1
 	pID := &ID
 	Name := "Tom"
@@ -74,8 +88,13 @@ func main() {
 	fmt.Printf("Player name is %s at HeapRAM addr = %p.\n", pPlayer.getName(), player.pName)
 
 	// WARNING: Also valid syntax.
-	fmt.Printf("\n[Alternative call to interface BarEntity]\nPlayer ID is %v at HeapRAM addr = %v.\nPlayer name is %v at HeapRAM addr = %v.\n\n", player.getID(), player.pID, player.getName(), player.pName)
+	fmt.Printf("\n[Alternative call via `%%v`]\nPlayer ID is %v at HeapRAM addr = %v.\nPlayer name is %v at HeapRAM addr = %v.\n\n", player.getID(), player.pID, player.getName(), player.pName)
+
+	printIDOfEntity(pPlayer)
+	printNameOfEntity(pPlayer)
 
-	printIDOfFooEntityByCastingToStruct(player)
-	printNameOfBarEntityByCastingToPtrToStruct(pPlayer)
+	reg := entity.NewRegistry[entity.Entity[int], int]()
+	reg.Put(pPlayer)
+	reg.Put(&Player{ID: 42, Name: "Jerry"})
+	printIDsOfAllEntitiesInRegistry(reg)
 }
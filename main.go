@@ -1,10 +1,12 @@
 package main
 
 import (
-	"container/heap"
 	"fmt"
+	"math"
 	"math/rand"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,6 +20,12 @@ type Player struct {
 	Name      string `json:"name"`
 }
 
+// EntityID implements `entity.Entity[int]` (see pkg/entity and basics.go),
+// letting a `*Player` live in the same generic registry as a `*TestPlayer`.
+func (pPlayer *Player) EntityID() int {
+	return pPlayer.ID
+}
+
 type roomState struct {
 	IDLE          int
 	WAITING       int
@@ -36,38 +44,101 @@ func calRoomScore(playerListCount int, capacity int, currentRoomState int) float
 	return -7.8125*d2 + 5 - float32(currentRoomState)
 }
 
+// roomIdleDecayPerSecond is how much score an idle room loses for every
+// second nobody has joined or left it, so that rooms which have gone quiet
+// sink in the matchmaking queue even though `calRoomScore` alone is static
+// between player-count changes.
+const roomIdleDecayPerSecond float32 = 0.01
+
 type Room struct {
 	ID       int             `json:"id"`
 	Capacity int             `json:"capacity"`
 	Players  map[int]*Player `json:"players"`
-	Score    float32
-	State    int
-	Index    int
+
+	// scoreBits and state back the exported Score()/State() accessors below.
+	// They're `atomic` rather than plain fields so that scoreboard-style
+	// reads (see RoomRegistry) need no lock, even though mutations still only
+	// happen under the owning shard's mutex in RoomRegistry.JoinAnyRoom.
+	scoreBits      atomic.Uint32
+	state          atomic.Int32
+	LastActivityAt int64
+}
+
+// Score returns the room's current score as set by the last call to
+// updateScore, safe to read without holding any lock.
+func (pR *Room) Score() float32 {
+	return math.Float32frombits(pR.scoreBits.Load())
+}
+
+func (pR *Room) setScore(score float32) {
+	pR.scoreBits.Store(math.Float32bits(score))
+}
+
+// EntityID implements `entity.Entity[int]` (see pkg/entity), letting a
+// `*Room` live in the RoomRegistry's pool-backed entity.Registry.
+func (pR *Room) EntityID() int {
+	return pR.ID
+}
+
+// State returns the room's current lifecycle state (see `roomState`), safe
+// to read without holding any lock.
+func (pR *Room) State() int {
+	return int(pR.state.Load())
+}
+
+func (pR *Room) setState(state int) {
+	pR.state.Store(int32(state))
+}
+
+// CompareAndSwapState atomically transitions the room's state from old to
+// new, returning false if another goroutine already moved it away from old.
+func (pR *Room) CompareAndSwapState(old int, newState int) bool {
+	return pR.state.CompareAndSwap(int32(old), int32(newState))
+}
+
+// newRoom builds a Room with its initial score already computed, since
+// `Score`/`State` are no longer plain struct fields that a literal can set
+// directly.
+func newRoom(id int, capacity int, state int, now int64) *Room {
+	pRoom := &Room{
+		ID:             id,
+		Capacity:       capacity,
+		Players:        make(map[int]*Player),
+		LastActivityAt: now,
+	}
+	pRoom.setState(state)
+	pRoom.updateScore()
+	return pRoom
 }
 
 func (pR *Room) updateScore() {
-	pR.Score = calRoomScore(len(pR.Players), pR.Capacity, pR.State)
+	pR.setScore(calRoomScore(len(pR.Players), pR.Capacity, pR.State()))
 }
 
-func (pR *Room) addPlayerIfPossible(pPlayer *Player) bool {
+func (pR *Room) addPlayerIfPossible(pPlayer *Player, now int64) bool {
 	// TODO: Check feasibility first.
 	pR.Players[pPlayer.ID] = pPlayer
 	pR.updateScore()
+	pR.LastActivityAt = now
 	return true
 }
 
-var RoomHeapMux sync.Mutex
-
-// Reference https://golang.org/pkg/container/heap/.
-type RoomHeap []Room
-
-func (pPq *RoomHeap) PrintInOrder() {
-	pq := *pPq
-	fmt.Printf("The RoomHeap instance now contains:\n")
-	for i := 0; i < len(pq); i++ {
-		fmt.Printf("{index: %d, roomID: %d, score: %.2f} ", i, pq[i].ID, pq[i].Score)
+// roomPriority is the `LazyQueue` priority callback: it recomputes a room's
+// real, decayed score at instant `now`, reflecting however long the room has
+// sat idle since its last join/leave.
+func roomPriority(pRoom *Room, now int64) float32 {
+	idleMillis := now - pRoom.LastActivityAt
+	if idleMillis < 0 {
+		idleMillis = 0
 	}
-	fmt.Printf("\n")
+	return pRoom.Score() - roomIdleDecayPerSecond*float32(idleMillis)/1000.0
+}
+
+// roomMaxPriority is the `LazyQueue` maxPriority callback: the highest a
+// room's score could possibly be by `until` is its un-decayed base score,
+// i.e. as if it were to become active again right at `until`.
+func roomMaxPriority(pRoom *Room, until int64) float32 {
+	return pRoom.Score()
 }
 
 /*
@@ -77,50 +148,67 @@ To be quantitative, `make([]*Room, 1024)` immediately takes 1024*32_bits/ptr, an
 
 In contrast, `make([]Room, 1024)` immediately takes 1024*sizeof(Room)_bits/ptr, but won't grow with later assignment.
 
-This is why we're having `Room.Players map[int]*Player` here.
+This is why we're having `Room.Players map[int]*Player` here, and why `prque.NewLazy[*Room, float32]` is instantiated with `*Room` rather than `Room`.
 */
 
-func (pq RoomHeap) Len() int { return len(pq) }
-
-func (pq RoomHeap) Less(i, j int) bool {
-	return pq[i].Score > pq[j].Score
-}
-
-func (pq *RoomHeap) Swap(i, j int) {
-	(*pq)[i], (*pq)[j] = (*pq)[j], (*pq)[i]
-	(*pq)[i].Index = i
-	(*pq)[j].Index = j
-}
-
-func (pq *RoomHeap) Push(pItem interface{}) {
-	// NOTE: Must take input param type `*Room` here.
-	n := len(*pq)
-	pItem.(*Room).Index = n
-	*pq = append(*pq, *(pItem.(*Room)))
-}
-
-func (pq *RoomHeap) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	if n == 0 {
-		panic(fmt.Sprintf("Popping on an empty heap is not allowed.\n"))
+// scalingDemoPlayerCount / scalingDemoRoomCount / scalingDemoRoomCapacity size
+// the shard-scaling demonstration in main(): enough players contending for
+// the same rooms that the per-join lock-contention difference between one
+// shard and GOMAXPROCS shards actually shows up in wall-clock time, with
+// capacity generous enough that join failures aren't the thing being
+// measured (addPlayerIfPossible doesn't yet enforce Capacity at all -- see
+// its TODO -- so this also happens to sidestep that).
+const scalingDemoPlayerCount = 4000
+const scalingDemoRoomCount = 64
+const scalingDemoRoomCapacity = 1 << 30
+
+// simulateMatchmaking spins up its own, isolated RoomRegistry with
+// shardCount shards and roomCount rooms, then has playerCount goroutines
+// race to JoinAnyRoom concurrently. It returns how many joins succeeded and
+// how long the whole burst took to drain, so callers can compare wall-clock
+// time across different shardCounts under the same load.
+func simulateMatchmaking(shardCount int, playerCount int, roomCount int, roomCapacity int) (succeeded int32, elapsed time.Duration) {
+	now := UnixtimeMilli()
+	reg := NewRoomRegistry(shardCount, roomPriority, roomMaxPriority, int64(1000))
+	for i := 0; i < roomCount; i++ {
+		reg.AddRoom(newRoom(i, roomCapacity, RoomState.IDLE, now), now)
 	}
-	item := old[n-1]
-	if item.Score <= float32(0.0) {
-		panic(fmt.Sprintf("No available room at the moment.\n"))
+	reg.RefreshAll(now)
+
+	var succeededCount int32
+	var wg sync.WaitGroup
+	wg.Add(playerCount)
+	start := time.Now()
+	for i := 0; i < playerCount; i++ {
+		go func(id int) {
+			defer wg.Done()
+			tPlyr := &Player{CreatedAt: now, ID: id, Name: fmt.Sprintf("ScalingPlayer#%d", id)}
+			if _, ok := reg.JoinAnyRoom(tPlyr, UnixtimeMilli()); ok {
+				atomic.AddInt32(&succeededCount, 1)
+			}
+		}(i)
 	}
-	item.Index = -1 // for safety
-	*pq = old[0 : n-1]
-	// NOTE: Must return instance which is directly castable to type `*Room` here.
-	return (&item)
+	wg.Wait()
+	return succeededCount, time.Since(start)
 }
 
-func (pq *RoomHeap) update(pItem *Room, Score float32) {
-	// NOTE: Must use type `*Room` here.
-	heap.Fix(pq, pItem.Index)
+func printRoomsInOrder(pReg *RoomRegistry, now int64) {
+	// RoomIDs runs through the pool-backed entity.RegistryPool on every call,
+	// i.e. once per successful join -- the high-churn path its doc comment
+	// is about.
+	fmt.Printf("The RoomRegistry now contains (IDs: %v):\n", pReg.RoomIDs())
+	pReg.RangeRooms(func(pRoom *Room) bool {
+		fmt.Printf("{roomID: %d, score: %.2f} ", pRoom.ID, roomPriority(pRoom, now))
+		return true
+	})
+	fmt.Printf("\n")
 }
 
 func main() {
+	// Runs the pkg/entity demo that used to be basics.go's own `main`
+	// (see runEntityDemo in basics.go) before getting into matchmaking.
+	runEntityDemo()
+
 	// Init "pseudo class constants".
 	RoomState = &roomState{
 		IDLE:          0,
@@ -131,24 +219,27 @@ func main() {
 	}
 
 	initialCountOfRooms := 5
-	pq := make(RoomHeap, initialCountOfRooms)
+	roomRefreshWindowMillis := int64(1000)
+	shardCount := runtime.GOMAXPROCS(0)
+	roomRegistry := NewRoomRegistry(shardCount, roomPriority, roomMaxPriority, roomRefreshWindowMillis)
 
 	roomCapacity := 4
 
+	initNow := UnixtimeMilli()
 	for i := 0; i < initialCountOfRooms; i++ {
-		players := make(map[int]*Player)
-		currentRoomState := RoomState.IDLE
-		pq[i] = Room{
-			Players:  players,
-			Capacity: roomCapacity,
-			Score:    calRoomScore(len(players) /* Initially 0. */, roomCapacity, currentRoomState),
-			State:    currentRoomState,
-			ID:       i,
-			Index:    i,
-		}
+		pRoom := newRoom(i, roomCapacity, RoomState.IDLE, initNow)
+		roomRegistry.AddRoom(pRoom, initNow)
 	}
-	heap.Init(&pq)
-	fmt.Printf("RoomHeap is initialized.\n")
+	roomRegistry.RefreshAll(initNow)
+	fmt.Printf("The RoomRegistry is initialized with %d rooms sharded across %d shards (GOMAXPROCS=%d).\n", initialCountOfRooms, shardCount, shardCount)
+
+	// Demonstrate that sharding the registry actually buys wall-clock
+	// scaling under contention: the same player burst against a
+	// single-shard registry versus a GOMAXPROCS-sharded one.
+	singleShardSucceeded, singleShardElapsed := simulateMatchmaking(1, scalingDemoPlayerCount, scalingDemoRoomCount, scalingDemoRoomCapacity)
+	fmt.Printf("[scaling demo] shardCount=1: %d/%d players joined in %v.\n", singleShardSucceeded, scalingDemoPlayerCount, singleShardElapsed)
+	shardedSucceeded, shardedElapsed := simulateMatchmaking(shardCount, scalingDemoPlayerCount, scalingDemoRoomCount, scalingDemoRoomCapacity)
+	fmt.Printf("[scaling demo] shardCount=%d (GOMAXPROCS): %d/%d players joined in %v.\n", shardCount, shardedSucceeded, scalingDemoPlayerCount, shardedElapsed)
 
 	var wasteChanWg sync.WaitGroup
 
@@ -158,12 +249,10 @@ func main() {
 	 *
 	 * Note that `wasteChanListCloseSignalChan` need NOT be closed explicitly.
 	 */
-	aWasteChan := make(chan interface{}, 1024)
 	anotherWasteChan := make(chan interface{}, 1024)
-	wasteChanList := make([]chan interface{}, 2)
+	wasteChanList := make([]chan interface{}, 1)
 
-	wasteChanList[0] = aWasteChan
-	wasteChanList[1] = anotherWasteChan
+	wasteChanList[0] = anotherWasteChan
 
 	wasteChanListCloseSignalChan := make(chan bool, 1)
 
@@ -192,9 +281,37 @@ func main() {
 		return nil
 	}
 	wasteChanWg.Add(len(wasteChanList))
-	go wasteChanLoop(aWasteChan, "aWasteChan")
 	go wasteChanLoop(anotherWasteChan, "anotherWasteChan")
 
+	/**
+	 * Dedicated goroutine that periodically calls `roomRegistry.RefreshAll` so
+	 * that rooms' `maxPriority` bounds get recomputed on a cadence independent
+	 * of how often players happen to join, replacing what used to be
+	 * `aWasteChan`'s loop.
+	 */
+	roomScoreRefreshCloseSignalChan := make(chan bool, 1)
+	roomScoreRefreshLoop := func() error {
+		defer func() {
+			wasteChanWg.Done()
+			fmt.Printf("The loop for `roomScoreRefresh` is ended.\n")
+		}()
+		for {
+			select {
+			case trueOrFalse := <-roomScoreRefreshCloseSignalChan:
+				fmt.Printf("Received %v from roomScoreRefreshCloseSignalChan in loop for `roomScoreRefresh`.\n", trueOrFalse)
+				if trueOrFalse == true {
+					return nil
+				}
+			default:
+			}
+			roomRegistry.RefreshAll(UnixtimeMilli())
+			time.Sleep(time.Millisecond * time.Duration(roomRefreshWindowMillis))
+		}
+		return nil
+	}
+	wasteChanWg.Add(1)
+	go roomScoreRefreshLoop()
+
 	var mainWg sync.WaitGroup
 	initialCountOfPlayers := 100
 	mainWg.Add(initialCountOfPlayers)
@@ -212,22 +329,19 @@ func main() {
 			randomMillisToSleep := rand.Intn(100) // [0, 100) milliseconds.
 			time.Sleep(time.Duration(randomMillisToSleep) * time.Millisecond)
 
-			RoomHeapMux.Lock()
-			defer RoomHeapMux.Unlock()
 			defer func() {
-				// Will immediately execute `RoomHeapMux.Unlock()` and then `mainWg.Done()` in order if panics.
 				if r := recover(); r != nil {
 					fmt.Println("Recovered from a panic: ", r)
 				}
 			}()
-			pRoom := heap.Pop(&pq).(*Room)
-			fmt.Printf("Successfully popped room %v for player %v.\n", pRoom.ID, tPlyr.Name)
+			pRoom, ok := roomRegistry.JoinAnyRoom(tPlyr, UnixtimeMilli())
+			if !ok {
+				panic(fmt.Sprintf("No available room at the moment.\n"))
+			}
+			fmt.Printf("Successfully joined room %v for player %v.\n", pRoom.ID, tPlyr.Name)
 			randomMillisToSleepAgain := rand.Intn(100) // [0, 100) milliseconds.
 			time.Sleep(time.Duration(randomMillisToSleepAgain) * time.Millisecond)
-			pRoom.addPlayerIfPossible(tPlyr)
-			heap.Push(&pq, pRoom)
-			(&pq).update(pRoom, pRoom.Score)
-			pq.PrintInOrder()
+			printRoomsInOrder(roomRegistry, UnixtimeMilli())
 		}(&testingPlayer)
 	}
 
@@ -237,7 +351,7 @@ func main() {
 	now = UnixtimeMilli()
 	fmt.Printf("All `goroutines of room joining` ended at %v.\n", now)
 	wasteChanListCloseSignalChan <- true
-	wasteChanListCloseSignalChan <- true
+	roomScoreRefreshCloseSignalChan <- true
 	wasteChanWg.Wait()
 	now = UnixtimeMilli()
 	fmt.Printf("Exiting at %v.\n", now)
@@ -0,0 +1,66 @@
+// Package entity provides a small reflection-free entity system: a generic
+// Entity[ID] interface plus a sync.Map-backed Registry, replacing the
+// `e.(TestPlayer)` / `e.(*TestPlayer)` type assertions that basics.go used to
+// rely on.
+package entity
+
+import "sync"
+
+// Entity is implemented by anything that can be looked up by a stable,
+// comparable ID -- e.g. `*TestPlayer` or `*Player` by their int ID.
+type Entity[ID comparable] interface {
+	EntityID() ID
+}
+
+// Registry is a concurrent-safe collection of entities of type T, keyed by
+// their own EntityID(). It's backed by sync.Map rather than a mutex+map pair
+// so that high-churn Get/Put/Delete traffic (e.g. matchmaking) doesn't
+// serialize through a single lock.
+type Registry[T Entity[ID], ID comparable] struct {
+	items sync.Map // ID -> T
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry[T Entity[ID], ID comparable]() *Registry[T, ID] {
+	return &Registry[T, ID]{}
+}
+
+// Put stores (or replaces) an entity keyed by its own EntityID().
+func (pReg *Registry[T, ID]) Put(item T) {
+	pReg.items.Store(item.EntityID(), item)
+}
+
+// Get looks up an entity by ID.
+func (pReg *Registry[T, ID]) Get(id ID) (T, bool) {
+	v, ok := pReg.items.Load(id)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return v.(T), true
+}
+
+// Delete removes an entity by ID, if present.
+func (pReg *Registry[T, ID]) Delete(id ID) {
+	pReg.items.Delete(id)
+}
+
+// Range calls fn for every entity currently in the registry, in no
+// particular order, stopping early if fn returns false.
+func (pReg *Registry[T, ID]) Range(fn func(T) bool) {
+	pReg.items.Range(func(_, v interface{}) bool {
+		return fn(v.(T))
+	})
+}
+
+// View applies fn to every entity in pReg and collects the results. It's a
+// free function rather than a method because Go methods can't introduce type
+// parameters beyond their receiver's (here, a `U` independent of T/ID).
+func View[T Entity[ID], ID comparable, U any](pReg *Registry[T, ID], fn func(T) U) []U {
+	var out []U
+	pReg.Range(func(item T) bool {
+		out = append(out, fn(item))
+		return true
+	})
+	return out
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"sort"
+	"testing"
+)
+
+// idOnly is a minimal Entity[int] used to exercise Registry/View without
+// depending on any concrete game type.
+type idOnly int
+
+func (id idOnly) EntityID() int { return int(id) }
+
+// namedEntity is a second, distinct concrete type implementing Entity[int],
+// used alongside idOnly to check that a single Registry can hold
+// heterogeneous entities behind the Entity[int] interface.
+type namedEntity struct {
+	id   int
+	name string
+}
+
+func (pE *namedEntity) EntityID() int { return pE.id }
+
+func TestRegistryPutGetDelete(t *testing.T) {
+	reg := NewRegistry[idOnly, int]()
+	reg.Put(idOnly(1))
+	reg.Put(idOnly(2))
+
+	if got, ok := reg.Get(1); !ok || got != idOnly(1) {
+		t.Fatalf("expected to find idOnly(1), got %v, ok=%v", got, ok)
+	}
+	if _, ok := reg.Get(3); ok {
+		t.Fatalf("expected no entity keyed 3")
+	}
+
+	reg.Delete(1)
+	if _, ok := reg.Get(1); ok {
+		t.Fatalf("expected idOnly(1) to be gone after Delete")
+	}
+}
+
+func TestRegistryHeterogeneousEntities(t *testing.T) {
+	reg := NewRegistry[Entity[int], int]()
+	reg.Put(idOnly(1))
+	reg.Put(&namedEntity{id: 2, name: "Tom"})
+
+	ids := View(reg, func(e Entity[int]) int { return e.EntityID() })
+	sort.Ints(ids)
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("expected IDs [1 2], got %v", ids)
+	}
+}
+
+func TestRegistryPoolViewMatchesPlainView(t *testing.T) {
+	reg := NewRegistry[idOnly, int]()
+	for i := 0; i < 5; i++ {
+		reg.Put(idOnly(i))
+	}
+
+	pool := NewRegistryPool[idOnly, int, int](2)
+	for round := 0; round < 3; round++ {
+		got := pool.View(reg, func(e idOnly) int { return e.EntityID() })
+		sort.Ints(got)
+		want := View(reg, func(e idOnly) int { return e.EntityID() })
+		sort.Ints(want)
+		if len(got) != len(want) {
+			t.Fatalf("round %d: expected %d entities, got %d", round, len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("round %d: expected %v, got %v", round, want, got)
+			}
+		}
+	}
+}
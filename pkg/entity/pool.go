@@ -0,0 +1,41 @@
+package entity
+
+import "sync"
+
+// RegistryPool recycles the `[]U` scratch slices that View grows while
+// collecting results, for callers that run a view very frequently on
+// short-lived requests (e.g. once per matchmaking join), so each call makes
+// only one right-sized result allocation instead of repeatedly regrowing a
+// fresh slice -- the same "get, reset, use, put back" shape `sync.Pool`
+// itself is built around in the Go runtime sources.
+type RegistryPool[T Entity[ID], ID comparable, U any] struct {
+	pool sync.Pool
+}
+
+// NewRegistryPool creates a RegistryPool whose scratch buffers start at the
+// given capacity.
+func NewRegistryPool[T Entity[ID], ID comparable, U any](initialCap int) *RegistryPool[T, ID, U] {
+	return &RegistryPool[T, ID, U]{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return make([]U, 0, initialCap)
+			},
+		},
+	}
+}
+
+// View behaves like the package-level View, but services the intermediate
+// `[]U` scratch slice from the pool instead of allocating it fresh, and
+// returns a right-sized copy so the pooled buffer stays safe to reuse.
+func (pPool *RegistryPool[T, ID, U]) View(pReg *Registry[T, ID], fn func(T) U) []U {
+	buf := pPool.pool.Get().([]U)
+	buf = buf[:0]
+	pReg.Range(func(item T) bool {
+		buf = append(buf, fn(item))
+		return true
+	})
+	out := make([]U, len(buf))
+	copy(out, buf)
+	pPool.pool.Put(buf)
+	return out
+}
@@ -0,0 +1,122 @@
+package prque
+
+// LazyQueue is a priority queue for items whose priority keeps drifting with
+// wall-clock time (e.g. `Room.Score` decaying while idle and climbing while
+// active), modeled on go-ethereum's lazy priority queue. It avoids an
+// O(log n) `heap.Fix` per item on every tick by only recomputing an item's
+// real priority when it is actually popped.
+//
+// LazyQueue is NOT safe for concurrent use on its own; callers are expected
+// to guard Push/Pop/Refresh with a single external mutex.
+type LazyQueue[T any, P Ordered] struct {
+	current, next *PriorityQueue[T, P]
+	priority      func(item T, now int64) P
+	maxPriority   func(item T, until int64) P
+	refreshWindow int64
+}
+
+// NewLazy creates an empty LazyQueue.
+//
+//   - priority computes an item's real, current priority at time `now`.
+//   - maxPriority computes an upper bound on what an item's priority could
+//     possibly rise to by time `until`, used to order the "next" heap so that
+//     Pop never has to look past an item whose bound is already too low.
+//   - refreshWindow is the duration (in the same unit as `now`, e.g.
+//     milliseconds from `UnixtimeMilli`) that a freshly computed maxPriority
+//     bound is trusted for, until the next Refresh.
+func NewLazy[T any, P Ordered](priority func(item T, now int64) P, maxPriority func(item T, until int64) P, refreshWindow int64) *LazyQueue[T, P] {
+	return &LazyQueue[T, P]{
+		current:       New[T, P](),
+		next:          New[T, P](),
+		priority:      priority,
+		maxPriority:   maxPriority,
+		refreshWindow: refreshWindow,
+	}
+}
+
+// Len returns the total number of items queued across both internal heaps.
+func (pLq *LazyQueue[T, P]) Len() int {
+	return pLq.current.Len() + pLq.next.Len()
+}
+
+// promoteIfEmpty brings "next" in as the new "current" when "current" has
+// run dry, so that an item Push'd back right after a Pop (the common
+// pop-mutate-push rejoin pattern) doesn't sit invisible to Pop/Peek until
+// some later, separately-triggered Refresh.
+func (pLq *LazyQueue[T, P]) promoteIfEmpty() {
+	if pLq.current.Len() == 0 {
+		pLq.current, pLq.next = pLq.next, pLq.current
+	}
+}
+
+// Push inserts item into the "next" heap, keyed by its upper-bound priority
+// for the upcoming refresh window starting at now.
+func (pLq *LazyQueue[T, P]) Push(item T, now int64) {
+	until := now + pLq.refreshWindow
+	pLq.next.Push(item, pLq.maxPriority(item, until))
+}
+
+// Pop removes and returns the item with the highest real priority at time
+// now among those in the "current" heap, along with that priority. Items
+// waiting in "next" are not compared against. ok is false if the queue is
+// empty.
+//
+// Internally it pulls from the "current" heap (promoting "next" into
+// "current" first if the latter has run dry) and recomputes each
+// candidate's real priority; as soon as that recomputed priority is no
+// longer beaten by the (still bound-based) new top of "current", or
+// "current" has nothing left, the candidate is returned. Otherwise the
+// candidate's real priority has drifted below what it was assumed to be, so
+// it's reinserted into "next" with a freshly computed bound and the search
+// continues.
+func (pLq *LazyQueue[T, P]) Pop(now int64) (item T, priority P, ok bool) {
+	for {
+		pLq.promoteIfEmpty()
+		if pLq.current.Len() == 0 {
+			var zero T
+			var zeroP P
+			return zero, zeroP, false
+		}
+		candidate, _ := pLq.current.Pop()
+		realPriority := pLq.priority(candidate, now)
+		if pLq.current.Len() == 0 {
+			return candidate, realPriority, true
+		}
+		_, topBound := pLq.current.Peek()
+		if realPriority >= topBound {
+			return candidate, realPriority, true
+		}
+		until := now + pLq.refreshWindow
+		pLq.next.Push(candidate, pLq.maxPriority(candidate, until))
+	}
+}
+
+// Peek returns the item currently at the front of the "current" heap and its
+// maxPriority bound, without popping it or recomputing its real priority
+// (promoting "next" into "current" first if the latter has run dry). This is
+// intentionally cheap: it's meant for cross-shard sampling (compare bounds
+// across several LazyQueues) before committing to a real Pop on the winning
+// one.
+func (pLq *LazyQueue[T, P]) Peek() (item T, bound P, ok bool) {
+	pLq.promoteIfEmpty()
+	if pLq.current.Len() == 0 {
+		var zero T
+		var zeroP P
+		return zero, zeroP, false
+	}
+	item, bound = pLq.current.Peek()
+	return item, bound, true
+}
+
+// Refresh drains whatever is left in the "current" heap into "next" (each
+// item getting a freshly computed maxPriority bound for the window starting
+// at now), then swaps "current" and "next". Callable on a ticker so that
+// decayed bounds are periodically recomputed without requiring a Pop.
+func (pLq *LazyQueue[T, P]) Refresh(now int64) {
+	until := now + pLq.refreshWindow
+	for pLq.current.Len() > 0 {
+		item, _ := pLq.current.Pop()
+		pLq.next.Push(item, pLq.maxPriority(item, until))
+	}
+	pLq.current, pLq.next = pLq.next, pLq.current
+}
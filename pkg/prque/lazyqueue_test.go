@@ -0,0 +1,167 @@
+package prque
+
+import (
+	"sync"
+	"testing"
+)
+
+// staleItem carries the data needed by the priority/maxPriority callbacks
+// used throughout this file: a fixed upper bound and the wall-clock instant
+// after which its real priority drops.
+type staleItem struct {
+	name       string
+	bound      float64
+	decaysAt   int64
+	lowPrio    float64
+	beforePrio float64
+}
+
+func staleItemPriority(item staleItem, now int64) float64 {
+	if now >= item.decaysAt {
+		return item.lowPrio
+	}
+	return item.beforePrio
+}
+
+func staleItemMaxPriority(item staleItem, until int64) float64 {
+	return item.bound
+}
+
+// TestLazyQueuePopVisibleWithoutExplicitRefresh exercises the pop-mutate-push
+// rejoin pattern `RoomRegistry.JoinAnyRoom` uses on every successful room join, with no
+// Refresh call in between: an item popped and immediately pushed back must
+// still be poppable right away, not only after the next Refresh.
+func TestLazyQueuePopVisibleWithoutExplicitRefresh(t *testing.T) {
+	lq := NewLazy[int, int](
+		func(item int, now int64) int { return item },
+		func(item int, until int64) int { return item },
+		1000,
+	)
+
+	lq.Push(1, 0)
+	lq.Push(2, 0)
+	lq.Refresh(0) // called once only, moving the initial batch into "current"
+
+	for i := 0; i < 5; i++ {
+		got, _, ok := lq.Pop(0)
+		if !ok {
+			t.Fatalf("round %d: expected an item to be popped", i)
+		}
+		lq.Push(got, 0)
+	}
+
+	if _, _, ok := lq.Peek(); !ok {
+		t.Fatalf("expected queue to still report an item after repeated pop/push without Refresh")
+	}
+}
+
+// TestLazyQueuePopReinsertsStaleItem exercises the branch where the item
+// popped off "current" no longer holds the true maximum real priority once
+// recomputed, and must be reinserted into "next" instead of being returned.
+func TestLazyQueuePopReinsertsStaleItem(t *testing.T) {
+	lq := NewLazy[staleItem, float64](staleItemPriority, staleItemMaxPriority, 1000)
+
+	itemA := staleItem{name: "A", bound: 10, decaysAt: 100, lowPrio: 1, beforePrio: 10}
+	itemB := staleItem{name: "B", bound: 5, decaysAt: 100, lowPrio: 5, beforePrio: 5}
+
+	lq.Push(itemA, 0)
+	lq.Push(itemB, 0)
+	lq.Refresh(0) // moves both items from "next" into "current"
+
+	got, priority, ok := lq.Pop(200)
+	if !ok {
+		t.Fatalf("expected an item to be popped")
+	}
+	if got.name != "B" {
+		t.Fatalf("expected stale item A to be skipped in favor of B, got %q", got.name)
+	}
+	if priority != 5 {
+		t.Fatalf("expected B's real priority 5, got %v", priority)
+	}
+
+	// A must have been reinserted into "next" rather than dropped.
+	if lq.current.Len() != 0 {
+		t.Fatalf("expected current heap to be drained, has %d items left", lq.current.Len())
+	}
+	if lq.next.Len() != 1 {
+		t.Fatalf("expected the stale item to be reinserted into next, next has %d items", lq.next.Len())
+	}
+
+	lq.Refresh(200)
+	got, priority, ok = lq.Pop(200)
+	if !ok {
+		t.Fatalf("expected the reinserted item to be poppable after Refresh")
+	}
+	if got.name != "A" {
+		t.Fatalf("expected the reinserted item A, got %q", got.name)
+	}
+	if priority != 1 {
+		t.Fatalf("expected A's decayed priority 1, got %v", priority)
+	}
+}
+
+// TestLazyQueueConcurrentPushPopRefresh drives Push, Pop and Refresh from
+// many goroutines guarded by a single external mutex, the usage pattern this
+// type is documented to require, and checks the run completes without losing
+// or duplicating items.
+func TestLazyQueueConcurrentPushPopRefresh(t *testing.T) {
+	constPriority := func(item int, now int64) float64 { return float64(item) }
+	constMaxPriority := func(item int, until int64) float64 { return float64(item) }
+	lq := NewLazy[int, float64](constPriority, constMaxPriority, 0)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	const producers = 8
+	const itemsPerProducer = 50
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		go func(base int) {
+			defer wg.Done()
+			for i := 0; i < itemsPerProducer; i++ {
+				mu.Lock()
+				lq.Push(base*itemsPerProducer+i, 0)
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	lq.Refresh(0)
+	mu.Unlock()
+
+	popped := make(map[int]bool)
+	var poppedMu sync.Mutex
+	const consumers = 8
+	wg.Add(consumers)
+	for c := 0; c < consumers; c++ {
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				if lq.Len() == 0 {
+					mu.Unlock()
+					return
+				}
+				lq.Refresh(0)
+				item, _, ok := lq.Pop(0)
+				mu.Unlock()
+				if !ok {
+					return
+				}
+				poppedMu.Lock()
+				if popped[item] {
+					t.Errorf("item %d popped more than once", item)
+				}
+				popped[item] = true
+				poppedMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(popped) != producers*itemsPerProducer {
+		t.Fatalf("expected %d distinct items popped, got %d", producers*itemsPerProducer, len(popped))
+	}
+}
@@ -0,0 +1,137 @@
+// Package prque provides a generic priority queue, following the same
+// `container/heap`-backed approach as go-ethereum's `common/prque` generics
+// refactor (https://github.com/ethereum/go-ethereum/tree/master/common/prque),
+// but keyed on a caller-supplied `Ordered` priority instead of a fixed `float32`.
+package prque
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Ordered mirrors the subset of `golang.org/x/exp/constraints.Ordered` that a
+// priority actually needs; kept local so this package has no external deps.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Element is the handle returned by Push, used to later Update or Remove the
+// same item without having to re-locate it. Its `index` field is kept in sync
+// by the internal heap on every Swap, the same way `Room.Index` used to be
+// maintained by `RoomHeap.Swap` before this package existed.
+type Element[T any, P Ordered] struct {
+	value    T
+	priority P
+	index    int
+}
+
+// Index returns this element's current position in the underlying heap.
+func (pE *Element[T, P]) Index() int { return pE.index }
+
+// Value returns the value this element carries, e.g. for read-only inspection
+// such as debug printing, without popping it off the queue.
+func (pE *Element[T, P]) Value() T { return pE.value }
+
+// Priority returns this element's current priority.
+func (pE *Element[T, P]) Priority() P { return pE.priority }
+
+type innerHeap[T any, P Ordered] []*Element[T, P]
+
+func (pH innerHeap[T, P]) Len() int { return len(pH) }
+
+func (pH innerHeap[T, P]) Less(i, j int) bool {
+	// Max-heap: the highest priority sits at index 0, matching the old
+	// `RoomHeap.Less` which popped the highest-score room first.
+	return pH[i].priority > pH[j].priority
+}
+
+func (pH innerHeap[T, P]) Swap(i, j int) {
+	pH[i], pH[j] = pH[j], pH[i]
+	pH[i].index = i
+	pH[j].index = j
+}
+
+func (pH *innerHeap[T, P]) Push(x interface{}) {
+	item := x.(*Element[T, P])
+	item.index = len(*pH)
+	*pH = append(*pH, item)
+}
+
+func (pH *innerHeap[T, P]) Pop() interface{} {
+	old := *pH
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil // for safety
+	item.index = -1
+	*pH = old[0 : n-1]
+	return item
+}
+
+// PriorityQueue is a generic max-priority-queue of values `T` ordered by
+// priority `P`. It is the generics-refactored replacement for the
+// hand-rolled `RoomHeap`.
+type PriorityQueue[T any, P Ordered] struct {
+	h innerHeap[T, P]
+}
+
+// New creates an empty PriorityQueue.
+func New[T any, P Ordered]() *PriorityQueue[T, P] {
+	pq := &PriorityQueue[T, P]{h: make(innerHeap[T, P], 0)}
+	heap.Init(&pq.h)
+	return pq
+}
+
+// Len returns the number of items currently queued.
+func (pPq *PriorityQueue[T, P]) Len() int { return pPq.h.Len() }
+
+// Push inserts value with the given priority and returns a handle that can
+// later be passed to Update or Remove.
+func (pPq *PriorityQueue[T, P]) Push(value T, priority P) *Element[T, P] {
+	item := &Element[T, P]{value: value, priority: priority}
+	heap.Push(&pPq.h, item)
+	return item
+}
+
+// Peek returns the highest-priority value without removing it.
+func (pPq *PriorityQueue[T, P]) Peek() (T, P) {
+	if pPq.h.Len() == 0 {
+		panic(fmt.Sprintf("Peeking an empty PriorityQueue is not allowed.\n"))
+	}
+	top := pPq.h[0]
+	return top.value, top.priority
+}
+
+// PeekElement returns the handle of the highest-priority item without
+// removing it, e.g. so its value can be mutated in place and then re-heapified
+// via Update rather than having to Pop and Push it back.
+func (pPq *PriorityQueue[T, P]) PeekElement() *Element[T, P] {
+	if pPq.h.Len() == 0 {
+		panic(fmt.Sprintf("Peeking an empty PriorityQueue is not allowed.\n"))
+	}
+	return pPq.h[0]
+}
+
+// Pop removes and returns the highest-priority value.
+func (pPq *PriorityQueue[T, P]) Pop() (T, P) {
+	if pPq.h.Len() == 0 {
+		panic(fmt.Sprintf("Popping on an empty PriorityQueue is not allowed.\n"))
+	}
+	item := heap.Pop(&pPq.h).(*Element[T, P])
+	return item.value, item.priority
+}
+
+// Remove removes the item referenced by pElem, wherever it currently sits in
+// the heap, and returns its value.
+func (pPq *PriorityQueue[T, P]) Remove(pElem *Element[T, P]) T {
+	item := heap.Remove(&pPq.h, pElem.index).(*Element[T, P])
+	return item.value
+}
+
+// Update changes the priority of the item referenced by pElem and re-heapifies
+// around it, replacing the old `heap.Fix(pq, pItem.Index)` dance.
+func (pPq *PriorityQueue[T, P]) Update(pElem *Element[T, P], newPriority P) {
+	pElem.priority = newPriority
+	heap.Fix(&pPq.h, pElem.index)
+}
@@ -0,0 +1,37 @@
+package prque
+
+import "testing"
+
+func TestPriorityQueuePopOrder(t *testing.T) {
+	pq := New[string, int]()
+	pq.Push("low", 1)
+	pq.Push("high", 3)
+	pq.Push("mid", 2)
+
+	for _, want := range []string{"high", "mid", "low"} {
+		got, _ := pq.Pop()
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestPriorityQueueEmptyPeekPanics(t *testing.T) {
+	pq := New[string, int]()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected Peek on an empty PriorityQueue to panic")
+		}
+	}()
+	pq.Peek()
+}
+
+func TestPriorityQueueEmptyPopPanics(t *testing.T) {
+	pq := New[string, int]()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected Pop on an empty PriorityQueue to panic")
+		}
+	}()
+	pq.Pop()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/genxium/GoStructPrac/pkg/entity"
+	"github.com/genxium/GoStructPrac/pkg/prque"
+)
+
+// roomIDPoolInitialCap sizes the scratch slices entity.RegistryPool recycles
+// for RoomIDs(); it only needs to roughly match the usual room count to avoid
+// the occasional regrow, not be an exact bound.
+const roomIDPoolInitialCap = 16
+
+// roomShard owns one slice of the matchmaking pool's rooms: its own
+// `LazyQueue` plus the mutex guarding it. Splitting the single `RoomHeapMux`
+// into N of these lets up to N goroutines make progress on unrelated shards
+// at once instead of serializing through one global lock.
+type roomShard struct {
+	mu sync.Mutex
+	lq *prque.LazyQueue[*Room, float32]
+}
+
+// RoomRegistry is the sharded replacement for the old `RoomHeapMux`-guarded
+// `RoomHeap`. Room lookup by ID goes through a lock-free
+// `entity.Registry`, while the score-ordered pool used by matchmaking is
+// split into `len(shards)` independently-locked sub-heaps.
+type RoomRegistry struct {
+	rooms      *entity.Registry[*Room, int]
+	roomIDPool *entity.RegistryPool[*Room, int, int]
+	shards     []*roomShard
+}
+
+// NewRoomRegistry creates a registry with `shardCount` sub-heaps, each using
+// the given LazyQueue callbacks and refresh window (see roomPriority /
+// roomMaxPriority).
+func NewRoomRegistry(shardCount int, priority func(*Room, int64) float32, maxPriority func(*Room, int64) float32, refreshWindowMillis int64) *RoomRegistry {
+	pReg := &RoomRegistry{
+		rooms:      entity.NewRegistry[*Room, int](),
+		roomIDPool: entity.NewRegistryPool[*Room, int, int](roomIDPoolInitialCap),
+		shards:     make([]*roomShard, shardCount),
+	}
+	for i := 0; i < shardCount; i++ {
+		pReg.shards[i] = &roomShard{
+			lq: prque.NewLazy[*Room, float32](priority, maxPriority, refreshWindowMillis),
+		}
+	}
+	return pReg
+}
+
+func (pReg *RoomRegistry) shardFor(roomID int) *roomShard {
+	return pReg.shards[roomID%len(pReg.shards)]
+}
+
+// AddRoom registers pRoom for lookup and inserts it into its shard's heap.
+func (pReg *RoomRegistry) AddRoom(pRoom *Room, now int64) {
+	pReg.rooms.Put(pRoom)
+	shard := pReg.shardFor(pRoom.ID)
+	shard.mu.Lock()
+	shard.lq.Push(pRoom, now)
+	shard.mu.Unlock()
+}
+
+// GetRoom looks up a room by ID without taking any shard lock.
+func (pReg *RoomRegistry) GetRoom(roomID int) (*Room, bool) {
+	return pReg.rooms.Get(roomID)
+}
+
+// RangeRooms calls fn for every registered room, in no particular order,
+// again without taking any shard lock -- a scoreboard-style reader only
+// needs the lock-free `Room.Score()`/`Room.State()` accessors.
+func (pReg *RoomRegistry) RangeRooms(fn func(pRoom *Room) bool) {
+	pReg.rooms.Range(fn)
+}
+
+// RoomIDs returns the IDs of every currently registered room, via the
+// pool-backed entity.RegistryPool so that callers driving this on every
+// single join (see printRoomsInOrder) gather the IDs into a recycled scratch
+// buffer; the returned `[]int` is still a fresh, right-sized copy each time.
+func (pReg *RoomRegistry) RoomIDs() []int {
+	return pReg.roomIDPool.View(pReg.rooms, func(pRoom *Room) int {
+		return pRoom.ID
+	})
+}
+
+// RefreshAll calls Refresh(now) on every shard's LazyQueue in turn.
+func (pReg *RoomRegistry) RefreshAll(now int64) {
+	for _, shard := range pReg.shards {
+		shard.mu.Lock()
+		shard.lq.Refresh(now)
+		shard.mu.Unlock()
+	}
+}
+
+// JoinAnyRoom picks the globally best room across all shards and adds
+// pPlayer to it.
+//
+// It runs in two phases:
+//  1. Sampling: every shard is visited with `TryLock`, so a shard that's
+//     momentarily busy (another goroutine committing a join) is simply
+//     skipped rather than blocked on -- its candidate is just stale for this
+//     round, which is fine since scores only drift slowly.
+//  2. Commit: the shard holding the best-sampled candidate is properly
+//     locked, the room is actually popped (recomputing its real, current
+//     priority), and its state is advanced with a CAS so a room that raced
+//     into battle between sampling and commit is detected and rejected
+//     rather than double-assigned.
+func (pReg *RoomRegistry) JoinAnyRoom(pPlayer *Player, now int64) (*Room, bool) {
+	var bestShard *roomShard
+	var bestBound float32
+	for _, shard := range pReg.shards {
+		if !shard.mu.TryLock() {
+			continue
+		}
+		_, bound, ok := shard.lq.Peek()
+		shard.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if bestShard == nil || bound > bestBound {
+			bestShard, bestBound = shard, bound
+		}
+	}
+	if bestShard == nil {
+		return nil, false
+	}
+
+	bestShard.mu.Lock()
+	defer bestShard.mu.Unlock()
+
+	pRoom, priority, ok := bestShard.lq.Pop(now)
+	if !ok {
+		return nil, false
+	}
+	if priority <= float32(0.0) {
+		// Below the matchmaking threshold for now, but still a perfectly
+		// joinable room later on -- push it back like the two rejection
+		// branches below do, rather than dropping it from the registry for
+		// good.
+		bestShard.lq.Push(pRoom, now)
+		return nil, false
+	}
+	if cur := pRoom.State(); cur != RoomState.IDLE && cur != RoomState.WAITING {
+		// The room moved on to battle/settlement/dismissal between sampling
+		// and commit; put it back untouched and report failure for this
+		// round rather than double-assigning a player into it.
+		bestShard.lq.Push(pRoom, now)
+		return nil, false
+	}
+	if !pRoom.CompareAndSwapState(pRoom.State(), RoomState.WAITING) {
+		// Lost a race with some other subsystem mutating this room's state;
+		// same as above, leave it be and let the caller retry.
+		bestShard.lq.Push(pRoom, now)
+		return nil, false
+	}
+
+	pRoom.addPlayerIfPossible(pPlayer, now)
+	bestShard.lq.Push(pRoom, now)
+	return pRoom, true
+}